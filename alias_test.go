@@ -0,0 +1,95 @@
+package orapass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestChkAliasLine_ServiceNameDisambiguates verifies that two @alias
+// entries sharing a host and port but differing only by service name
+// (the common consolidated-DB/PDB topology) are not conflated when
+// DbName is left unset and only ServiceName is given.
+func TestChkAliasLine_ServiceNameDisambiguates(t *testing.T) {
+	dir := t.TempDir()
+	tnsnames := `
+ORCLPDB1 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SID=orcl)(SERVICE_NAME=orclpdb1)))
+
+ORCLPDB2 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SID=orcl)(SERVICE_NAME=orclpdb2)))
+`
+	if err := os.WriteFile(filepath.Join(dir, "tnsnames.ora"), []byte(tnsnames), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TNS_ADMIN", dir)
+
+	p := Parser{Host: "dbhost", Port: "1521", Username: "scott", ServiceName: "orclpdb2"}
+
+	got, matched, err := p.chkAliasLine("@orclpdb1:scott:tiger")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if matched {
+		t.Errorf("ORCLPDB1 alias should not have matched a query for service orclpdb2, got %+v", got)
+	}
+
+	got, matched, err = p.chkAliasLine("@orclpdb2:scott:lion")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !matched {
+		t.Fatal("ORCLPDB2 alias should have matched a query for service orclpdb2")
+	}
+	if got.Password != "lion" {
+		t.Errorf("got password %q, want %q", got.Password, "lion")
+	}
+}
+
+// TestChkAliasLine_ServiceNameDisambiguatesNoSID covers the
+// overwhelmingly common real-world tnsnames.ora shape: entries that
+// define only SERVICE_NAME, with no SID at all. chkForMatch("", "")
+// must not be allowed to make the two aliases indistinguishable.
+func TestChkAliasLine_ServiceNameDisambiguatesNoSID(t *testing.T) {
+	dir := t.TempDir()
+	tnsnames := `
+ORCLPDB1 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SERVICE_NAME=orclpdb1)))
+
+ORCLPDB2 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SERVICE_NAME=orclpdb2)))
+`
+	if err := os.WriteFile(filepath.Join(dir, "tnsnames.ora"), []byte(tnsnames), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TNS_ADMIN", dir)
+
+	p := Parser{Host: "dbhost", Port: "1521", Username: "scott", ServiceName: "orclpdb2"}
+
+	got, matched, err := p.chkAliasLine("@orclpdb1:scott:tiger")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if matched {
+		t.Errorf("ORCLPDB1 alias (no SID) should not have matched a query for service orclpdb2, got %+v", got)
+	}
+
+	got, matched, err = p.chkAliasLine("@orclpdb2:scott:lion")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !matched {
+		t.Fatal("ORCLPDB2 alias (no SID) should have matched a query for service orclpdb2")
+	}
+	if got.Password != "lion" {
+		t.Errorf("got password %q, want %q", got.Password, "lion")
+	}
+}
@@ -0,0 +1,252 @@
+package orapass
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Entry is a single parsed orapass line, with @alias lines already
+// resolved against tnsnames.ora.
+type Entry struct {
+	Host        string
+	Port        string
+	DbName      string
+	ServiceName string
+	Username    string
+	Password    string
+}
+
+// buildEntries parses the (already decrypted, if applicable) contents
+// of an orapass file into a slice of Entry.
+func buildEntries(data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	re := regexp.MustCompile("^ *#")
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || re.MatchString(line) {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			tokens := strings.SplitN(strings.TrimPrefix(line, "@"), ":", 3)
+			if len(tokens) < 3 {
+				continue
+			}
+			d, err := resolveTNSAlias(os.Getenv, tokens[0])
+			if err != nil {
+				continue
+			}
+			entries = append(entries, Entry{
+				Host:        d.Host,
+				Port:        d.Port,
+				DbName:      d.DbName,
+				ServiceName: d.ServiceName,
+				Username:    tokens[1],
+				Password:    tokens[2],
+			})
+			continue
+		}
+
+		tokens := strings.SplitN(line, ":", 6)
+		if len(tokens) < 5 {
+			continue
+		}
+
+		var serviceName string
+		if len(tokens) == 6 {
+			serviceName = tokens[5]
+		}
+		entries = append(entries, Entry{
+			Host:        tokens[0],
+			Port:        tokens[1],
+			DbName:      tokens[2],
+			ServiceName: serviceName,
+			Username:    tokens[3],
+			Password:    tokens[4],
+		})
+	}
+	return entries, scanner.Err()
+}
+
+// Cache parses an orapass file once and serves Lookup from memory
+// instead of re-opening and re-scanning the file on every call. It
+// watches the file with fsnotify and rebuilds its entries whenever the
+// file changes, with a TTL-based re-stat as a fallback for filesystems
+// where fsnotify is unreliable (NFS, some containers). A Cache is safe
+// for concurrent use.
+type Cache struct {
+	path    string
+	ttl     time.Duration
+	entries atomic.Pointer[[]Entry]
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewCache parses path into a Cache and starts watching it for
+// changes. ttl is the interval at which the file is re-stat'd as a
+// fallback in case fsnotify events are missed or unsupported; pass 0
+// to rely on fsnotify alone.
+func NewCache(path string, ttl time.Duration) (*Cache, error) {
+	c := &Cache{path: path, ttl: ttl, done: make(chan struct{})}
+
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+
+	if watcher, err := fsnotify.NewWatcher(); err == nil {
+		if err := watcher.Add(path); err == nil {
+			c.watcher = watcher
+			go c.watchFile()
+		} else {
+			watcher.Close()
+		}
+	}
+
+	if ttl > 0 {
+		go c.pollTTL()
+	}
+
+	return c, nil
+}
+
+// Close stops the Cache's background watcher and poller goroutines.
+func (c *Cache) Close() error {
+	close(c.done)
+	if c.watcher != nil {
+		return c.watcher.Close()
+	}
+	return nil
+}
+
+// reload re-parses c.path and atomically swaps in the new entries.
+func (c *Cache) reload() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	if isEncrypted(data) {
+		passphrase, err := resolvePassphrase()
+		if err != nil {
+			return err
+		}
+		data, err = DecryptFile(data, passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := buildEntries(data)
+	if err != nil {
+		return err
+	}
+
+	c.entries.Store(&entries)
+	return nil
+}
+
+func (c *Cache) watchFile() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case event, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				// Editors and config-management tools commonly replace
+				// a file by writing a temp file and renaming it over
+				// the original path, which removes the inode the
+				// watch was attached to. Re-add the watch on the path
+				// so future changes keep being observed.
+				_ = c.watcher.Add(c.path)
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				_ = c.reload()
+			}
+		case _, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (c *Cache) pollTTL() {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			_ = c.reload()
+		}
+	}
+}
+
+// Lookup returns the first cached Entry matching host, port, db and
+// user, applying the same wildcard rules as Parser.GetPasswd. service,
+// when non-empty, also matches an Entry whose ServiceName matches it
+// even if db does not, mirroring searchFile's SID-or-service-name
+// matching.
+func (c *Cache) Lookup(host, port, db, service, user string) (Entry, bool) {
+	entries := c.entries.Load()
+	if entries == nil {
+		return Entry{}, false
+	}
+
+	for _, e := range *entries {
+		// e.DbName is empty for alias-derived entries whose
+		// tnsnames.ora descriptor gives only a SERVICE_NAME and no
+		// SID, the overwhelming majority case. chkForMatch("", "")
+		// would otherwise report a match by equality, so an empty
+		// e.DbName is only matched through ServiceName.
+		dbMatch := (e.DbName != "" && chkForMatch(db, e.DbName)) ||
+			(service != "" && chkForMatch(service, e.ServiceName))
+		if chkForMatch(host, e.Host) && chkForMatch(port, e.Port) &&
+			dbMatch && chkForMatch(user, e.Username) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// CacheResolver resolves passwords from a shared Cache instead of
+// re-scanning the orapass file on every Parser.GetPasswd call.
+type CacheResolver struct {
+	Cache *Cache
+}
+
+// Resolve implements Resolver.
+func (r CacheResolver) Resolve(p Parser) (Parser, error) {
+	var p2 Parser
+
+	if r.Cache == nil {
+		return p2, errors.New("CacheResolver.Cache is not set")
+	}
+
+	e, ok := r.Cache.Lookup(p.Host, p.Port, p.DbName, p.ServiceName, p.Username)
+	if !ok {
+		return p2, errors.New("Could not find a suitable password entry")
+	}
+
+	p2.Host = pickParm(p.Host, e.Host)
+	p2.Port = pickParm(p.Port, e.Port)
+	p2.DbName = pickParm(p.DbName, e.DbName)
+	p2.ServiceName = e.ServiceName
+	p2.Username = e.Username
+	p2.Password = e.Password
+	return p2, nil
+}
@@ -0,0 +1,242 @@
+package orapass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuildEntries(t *testing.T) {
+	data := []byte(`
+# a comment
+localhost:1521:emp:scott:tiger
+localhost:1521:*:scott:lion:orclsvc
+`)
+	entries, err := buildEntries(data)
+	if err != nil {
+		t.Fatalf("buildEntries: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Password != "tiger" {
+		t.Errorf("entries[0].Password = %q, want %q", entries[0].Password, "tiger")
+	}
+	if entries[1].ServiceName != "orclsvc" {
+		t.Errorf("entries[1].ServiceName = %q, want %q", entries[1].ServiceName, "orclsvc")
+	}
+}
+
+func TestBuildEntries_Alias(t *testing.T) {
+	dir := t.TempDir()
+	tnsnames := `
+ORCLPDB1 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SID=orcl)(SERVICE_NAME=orclpdb1)))
+
+ORCLPDB2 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SID=orcl)(SERVICE_NAME=orclpdb2)))
+`
+	if err := os.WriteFile(filepath.Join(dir, "tnsnames.ora"), []byte(tnsnames), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TNS_ADMIN", dir)
+
+	data := []byte("@orclpdb1:scott:tiger\n@orclpdb2:scott:lion\n")
+	entries, err := buildEntries(data)
+	if err != nil {
+		t.Fatalf("buildEntries: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	// Querying by ServiceName alone (no DbName) must disambiguate
+	// between the two aliases sharing a host, port and SID, the same
+	// way FileResolver's chkAliasLine does.
+	e, ok := lookupEntries(entries, "dbhost", "1521", "", "orclpdb2", "scott")
+	if !ok {
+		t.Fatal("expected a match for service orclpdb2")
+	}
+	if e.Password != "lion" {
+		t.Errorf("got password %q, want %q", e.Password, "lion")
+	}
+}
+
+func lookupEntries(entries []Entry, host, port, db, service, user string) (Entry, bool) {
+	for _, e := range entries {
+		dbMatch := (e.DbName != "" && chkForMatch(db, e.DbName)) ||
+			(service != "" && chkForMatch(service, e.ServiceName))
+		if chkForMatch(host, e.Host) && chkForMatch(port, e.Port) &&
+			dbMatch && chkForMatch(user, e.Username) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+func newTestCache(t *testing.T, contents string) (*Cache, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "orapass")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCache(path, 0)
+	if err != nil {
+		t.Fatalf("NewCache: %s", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, path
+}
+
+func TestCache_Lookup(t *testing.T) {
+	c, _ := newTestCache(t, "localhost:1521:emp:scott:tiger\nlocalhost:1521:*:scott:lion\n")
+
+	e, ok := c.Lookup("localhost", "1521", "emp", "", "scott")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if e.Password != "tiger" {
+		t.Errorf("got password %q, want %q", e.Password, "tiger")
+	}
+
+	if _, ok := c.Lookup("localhost", "1521", "emp", "", "walter"); ok {
+		t.Error("expected no match for an unknown user")
+	}
+}
+
+func TestCache_Lookup_ServiceName(t *testing.T) {
+	dir := t.TempDir()
+	tnsnames := `
+ORCLPDB1 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SID=orcl)(SERVICE_NAME=orclpdb1)))
+
+ORCLPDB2 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SID=orcl)(SERVICE_NAME=orclpdb2)))
+`
+	if err := os.WriteFile(filepath.Join(dir, "tnsnames.ora"), []byte(tnsnames), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TNS_ADMIN", dir)
+
+	c, _ := newTestCache(t, "@orclpdb1:scott:tiger\n@orclpdb2:scott:lion\n")
+
+	e, ok := c.Lookup("dbhost", "1521", "", "orclpdb2", "scott")
+	if !ok {
+		t.Fatal("expected a match for service orclpdb2")
+	}
+	if e.Password != "lion" {
+		t.Errorf("got password %q, want %q", e.Password, "lion")
+	}
+}
+
+// TestCache_Lookup_ServiceNameNoSID covers the overwhelmingly common
+// real-world tnsnames.ora shape: entries that define only
+// SERVICE_NAME, with no SID at all. chkForMatch("", "") must not be
+// allowed to make the two aliases indistinguishable.
+func TestCache_Lookup_ServiceNameNoSID(t *testing.T) {
+	dir := t.TempDir()
+	tnsnames := `
+ORCLPDB1 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SERVICE_NAME=orclpdb1)))
+
+ORCLPDB2 =
+  (DESCRIPTION=
+    (ADDRESS=(HOST=dbhost)(PORT=1521))
+    (CONNECT_DATA=(SERVICE_NAME=orclpdb2)))
+`
+	if err := os.WriteFile(filepath.Join(dir, "tnsnames.ora"), []byte(tnsnames), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TNS_ADMIN", dir)
+
+	c, _ := newTestCache(t, "@orclpdb1:scott:tiger\n@orclpdb2:scott:lion\n")
+
+	if _, ok := c.Lookup("dbhost", "1521", "", "orclpdb1", "scott"); !ok {
+		t.Fatal("expected a match for service orclpdb1")
+	}
+
+	e, ok := c.Lookup("dbhost", "1521", "", "orclpdb2", "scott")
+	if !ok {
+		t.Fatal("expected a match for service orclpdb2")
+	}
+	if e.Password != "lion" {
+		t.Errorf("got password %q, want %q", e.Password, "lion")
+	}
+}
+
+func TestCache_Reload(t *testing.T) {
+	c, path := newTestCache(t, "localhost:1521:emp:scott:tiger\n")
+
+	if err := os.WriteFile(path, []byte("localhost:1521:emp:scott:newpass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.reload(); err != nil {
+		t.Fatalf("reload: %s", err)
+	}
+
+	e, ok := c.Lookup("localhost", "1521", "emp", "", "scott")
+	if !ok {
+		t.Fatal("expected a match after reload")
+	}
+	if e.Password != "newpass" {
+		t.Errorf("got password %q, want %q", e.Password, "newpass")
+	}
+}
+
+func TestCacheResolver_Resolve(t *testing.T) {
+	c, _ := newTestCache(t, "localhost:1521:emp:scott:tiger\n")
+
+	r := CacheResolver{Cache: c}
+	got, err := r.Resolve(Parser{Host: "localhost", Port: "1521", DbName: "emp", Username: "scott"})
+	if err != nil {
+		t.Fatalf("Resolve: %s", err)
+	}
+	if got.Password != "tiger" {
+		t.Errorf("got password %q, want %q", got.Password, "tiger")
+	}
+}
+
+func TestCacheResolver_Resolve_NoCache(t *testing.T) {
+	var r CacheResolver
+	if _, err := r.Resolve(Parser{}); err == nil {
+		t.Error("expected an error when CacheResolver.Cache is not set")
+	}
+}
+
+func TestCache_TTLPoll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "orapass")
+	if err := os.WriteFile(path, []byte("localhost:1521:emp:scott:tiger\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCache(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewCache: %s", err)
+	}
+	defer c.Close()
+
+	if err := os.WriteFile(path, []byte("localhost:1521:emp:scott:newpass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if e, ok := c.Lookup("localhost", "1521", "emp", "", "scott"); ok && e.Password == "newpass" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("TTL poll did not pick up the updated file within the deadline")
+}
@@ -15,6 +15,25 @@
 //  Username - The username may be specified by the ORACLE_USER
 //      environment variable or by the -u flag. If neither are specified
 //      then this defaults to the logged in user.
+//
+//  Service name - The Oracle service name (as opposed to SID) may be
+//      specified by the -s flag.
+//
+//  Connect string - An EZConnect string or TNS descriptor may be
+//      specified by the -c flag to populate the host, port, database
+//      and service name in one go.
+//
+// The -encrypt and -decrypt flags encrypt or decrypt an orapass file
+// in place instead of looking up a password. Both use the passphrase
+// from the ORAPASS_KEY environment variable, prompting interactively
+// if it is not set.
+//
+// The -backend flag selects the secret-store backend to resolve the
+// password from: "file" (the default, an orapass file), "vault"
+// (HashiCorp Vault, via VAULT_ADDR/VAULT_TOKEN), "oci" (OCI Vault), or
+// "keyring" (the OS-native credential store). The -secret-path flag
+// supplies the backend-specific path template, secret OCID, or
+// keyring service name.
 package main
 
 import (
@@ -28,19 +47,63 @@ import (
 func main() {
 
 	var (
-		p      orap.Parser
-		fQuiet bool
+		p           orap.Parser
+		fQuiet      bool
+		fDebug      bool
+		fEncrypt    string
+		fDecrypt    string
+		fBackend    string
+		fSecretPath string
 	)
 	flag.StringVar(&p.Username, "u", "", "The username to obtain a password for. Overrides the ORACLE_USER environment variable. Defaults to the OS user.")
 	flag.StringVar(&p.Host, "h", "", "The hostname that the database is on. Overrides the ORACLE_HOST environment variable. Defaults to localhost.")
 	flag.StringVar(&p.Port, "p", "", "The port that the database is listening on. Overrides the ORACLE_PORT environment variable. Defaults to 1521.")
 	flag.StringVar(&p.DbName, "d", "", "The database to connect to. Overrides the ORACLE_SID environment variable.")
+	flag.StringVar(&p.ServiceName, "s", "", "The Oracle service name to connect to, if different from the SID.")
+	flag.StringVar(&p.ConnectString, "c", "", "An EZConnect string (user/pass@//host:port/service) or TNS descriptor to parse the host, port, database and service name from.")
 	flag.StringVar(&p.OrapassFile, "f", "", "The orapass file to search for first.")
-	flag.BoolVar(&p.Debug, "debug", false, "Debug mode.")
+	flag.BoolVar(&fDebug, "debug", false, "Debug mode. Writes trace/debug log output to stderr.")
 	flag.BoolVar(&fQuiet, "q", false, "Quiet mode. Do not print any error messages.")
+	flag.StringVar(&fEncrypt, "encrypt", "", "Encrypt the given plaintext orapass file in place and exit.")
+	flag.StringVar(&fDecrypt, "decrypt", "", "Decrypt the given orapass file in place to plaintext and exit.")
+	flag.StringVar(&fBackend, "backend", "file", "The secret backend to use: file, vault, oci, or keyring.")
+	flag.StringVar(&fSecretPath, "secret-path", "", "The backend-specific secret path/template (Vault), secret OCID (OCI), or service name (keyring).")
 
 	flag.Parse()
 
+	if fDebug {
+		p.Logger = orap.NewWriterLogger(os.Stderr)
+	}
+
+	switch fBackend {
+	case "", "file":
+		// p.Resolver left nil; GetPasswd defaults to orap.FileResolver.
+	case "vault":
+		p.Resolver = orap.VaultResolver{PathTemplate: fSecretPath}
+	case "oci":
+		p.Resolver = orap.OCIVaultResolver{SecretID: fSecretPath}
+	case "keyring":
+		p.Resolver = orap.KeyringResolver{Service: fSecretPath}
+	default:
+		os.Stderr.WriteString(fmt.Sprintf("unknown backend %q\n", fBackend))
+		os.Exit(1)
+	}
+
+	switch {
+	case fEncrypt != "":
+		if err := orap.EncryptFileInPlace(fEncrypt); err != nil {
+			os.Stderr.WriteString(fmt.Sprintf("%s\n", err))
+			os.Exit(1)
+		}
+		return
+	case fDecrypt != "":
+		if err := orap.DecryptFileInPlace(fDecrypt); err != nil {
+			os.Stderr.WriteString(fmt.Sprintf("%s\n", err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	p2, err := p.GetPasswd()
 	if err != nil {
 		if !fQuiet {
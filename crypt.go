@@ -0,0 +1,177 @@
+package orapass
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// orapassMagicHeader identifies an orapass file that has been
+// encrypted with EncryptFile. It is followed by a 16-byte salt, a
+// GCM nonce, then the AES-GCM ciphertext. Plaintext orapass files
+// (without this header) continue to be read as before.
+const orapassMagicHeader = "ORAPASS1\n"
+
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// isEncrypted reports whether data begins with the orapass encrypted
+// file header.
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(orapassMagicHeader))
+}
+
+// EncryptFile encrypts plaintext orapass file contents with a key
+// derived from passphrase and returns it prefixed with the orapass
+// header, a random salt and the AES-GCM nonce.
+func EncryptFile(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	var buf bytes.Buffer
+	buf.WriteString(orapassMagicHeader)
+	buf.Write(salt)
+	buf.Write(nonce)
+	buf.Write(ciphertext)
+	return buf.Bytes(), nil
+}
+
+// DecryptFile reverses EncryptFile, returning the plaintext orapass
+// file contents for an encrypted file produced by EncryptFile.
+func DecryptFile(data []byte, passphrase string) ([]byte, error) {
+	if !isEncrypted(data) {
+		return nil, errors.New("not an encrypted orapass file")
+	}
+	body := data[len(orapassMagicHeader):]
+	if len(body) < saltLen {
+		return nil, errors.New("encrypted orapass file is truncated")
+	}
+	salt, body := body[:saltLen], body[saltLen:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) < gcm.NonceSize() {
+		return nil, errors.New("encrypted orapass file is truncated")
+	}
+	nonce, ciphertext := body[:gcm.NonceSize()], body[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt orapass file (wrong passphrase?): %w", err)
+	}
+	return plaintext, nil
+}
+
+// newGCM derives a key from passphrase and salt via scrypt and
+// returns an AES-GCM AEAD built from it.
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// resolvePassphrase obtains the passphrase used to encrypt or decrypt
+// an orapass file, preferring the ORAPASS_KEY environment variable
+// and otherwise prompting interactively on the terminal.
+func resolvePassphrase() (string, error) {
+	if k := os.Getenv("ORAPASS_KEY"); k != "" {
+		return k, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Enter orapass passphrase: ")
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// EncryptFileInPlace reads the plaintext orapass file at path,
+// encrypts it with a passphrase from ORAPASS_KEY (or an interactive
+// prompt), and rewrites path with the encrypted contents, forcing
+// permissions to 0600.
+func EncryptFileInPlace(path string) error {
+	plaintext, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if isEncrypted(plaintext) {
+		return errors.New("file is already encrypted")
+	}
+
+	passphrase, err := resolvePassphrase()
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := EncryptFile(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, ciphertext, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// DecryptFileInPlace reads the encrypted orapass file at path,
+// decrypts it with a passphrase from ORAPASS_KEY (or an interactive
+// prompt), and rewrites path with the plaintext contents, forcing
+// permissions to 0600.
+func DecryptFileInPlace(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := resolvePassphrase()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := DecryptFile(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, plaintext, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
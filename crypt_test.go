@@ -0,0 +1,61 @@
+package orapass
+
+import "testing"
+
+func TestEncryptDecryptFile_RoundTrip(t *testing.T) {
+	plaintext := []byte("localhost:1521:emp:scott:tiger\n")
+
+	ciphertext, err := EncryptFile(plaintext, "s3cret")
+	if err != nil {
+		t.Fatalf("EncryptFile: %s", err)
+	}
+	if !isEncrypted(ciphertext) {
+		t.Fatal("EncryptFile output does not carry the orapass magic header")
+	}
+
+	got, err := DecryptFile(ciphertext, "s3cret")
+	if err != nil {
+		t.Fatalf("DecryptFile: %s", err)
+	}
+	if string(got) != string(plaintext) {
+		t.Errorf("got %q, want %q", got, plaintext)
+	}
+}
+
+func TestDecryptFile_WrongPassphrase(t *testing.T) {
+	ciphertext, err := EncryptFile([]byte("localhost:1521:emp:scott:tiger\n"), "s3cret")
+	if err != nil {
+		t.Fatalf("EncryptFile: %s", err)
+	}
+
+	if _, err := DecryptFile(ciphertext, "wrong"); err == nil {
+		t.Error("DecryptFile with the wrong passphrase should have returned an error")
+	}
+}
+
+func TestDecryptFile_Truncated(t *testing.T) {
+	ciphertext, err := EncryptFile([]byte("localhost:1521:emp:scott:tiger\n"), "s3cret")
+	if err != nil {
+		t.Fatalf("EncryptFile: %s", err)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"header only", []byte(orapassMagicHeader)},
+		{"header plus partial salt", append([]byte(orapassMagicHeader), ciphertext[len(orapassMagicHeader):len(orapassMagicHeader)+4]...)},
+		{"missing nonce and ciphertext", ciphertext[:len(orapassMagicHeader)+saltLen]},
+	}
+	for _, c := range cases {
+		if _, err := DecryptFile(c.data, "s3cret"); err == nil {
+			t.Errorf("%s: DecryptFile on truncated data should have returned an error", c.name)
+		}
+	}
+}
+
+func TestDecryptFile_NotEncrypted(t *testing.T) {
+	if _, err := DecryptFile([]byte("localhost:1521:emp:scott:tiger\n"), "s3cret"); err == nil {
+		t.Error("DecryptFile on plaintext input should have returned an error")
+	}
+}
@@ -0,0 +1,121 @@
+package orapass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Level is a log severity, ordered from most to least verbose.
+type Level int
+
+// The severities a Parser logs at, from most to least verbose.
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the upper-case name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return "INFO"
+}
+
+// slogLevel maps a Level onto the closest slog.Level. slog has no
+// Trace level, so LevelTrace maps one step below slog.LevelDebug.
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Logger receives structured log output from a Parser. Messages are
+// emitted with attributes such as file=, line= and field= describing
+// what was being evaluated, so a caller can filter or correlate them
+// without parsing free-form text.
+type Logger interface {
+	Log(level Level, msg string, attrs ...slog.Attr)
+}
+
+// NewSlogLogger builds a Logger that forwards to an slog.Handler.
+func NewSlogLogger(h slog.Handler) Logger {
+	return slogLogger{handler: h}
+}
+
+// NewWriterLogger builds a Logger that writes one newline-terminated,
+// level-prefixed line per message to w.
+func NewWriterLogger(w io.Writer) Logger {
+	return writerLogger{w: w}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(Level, string, ...slog.Attr) {}
+
+type slogLogger struct {
+	handler slog.Handler
+}
+
+func (l slogLogger) Log(level Level, msg string, attrs ...slog.Attr) {
+	ctx := context.Background()
+	sl := level.slogLevel()
+	if !l.handler.Enabled(ctx, sl) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), sl, msg, 0)
+	r.AddAttrs(attrs...)
+	_ = l.handler.Handle(ctx, r)
+}
+
+type writerLogger struct {
+	w io.Writer
+}
+
+func (l writerLogger) Log(level Level, msg string, attrs ...slog.Attr) {
+	var b []byte
+	b = append(b, level.String()...)
+	b = append(b, ' ')
+	b = append(b, msg...)
+	for _, a := range attrs {
+		b = append(b, ' ')
+		b = append(b, a.Key...)
+		b = append(b, '=')
+		b = fmt.Appendf(b, "%v", a.Value)
+	}
+	b = append(b, '\n')
+	_, _ = l.w.Write(b)
+}
+
+// logger returns p.Logger, or a no-op Logger if none was set.
+func (p *Parser) logger() Logger {
+	if p.Logger == nil {
+		return nopLogger{}
+	}
+	return p.Logger
+}
@@ -0,0 +1,61 @@
+package orapass
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestLevel_String(t *testing.T) {
+	cases := []struct {
+		level Level
+		want  string
+	}{
+		{LevelTrace, "TRACE"},
+		{LevelDebug, "DEBUG"},
+		{LevelInfo, "INFO"},
+		{LevelWarn, "WARN"},
+		{LevelError, "ERROR"},
+		{Level(99), "INFO"},
+	}
+	for _, c := range cases {
+		if got := c.level.String(); got != c.want {
+			t.Errorf("Level(%d).String() = %q, want %q", c.level, got, c.want)
+		}
+	}
+}
+
+func TestWriterLogger_Log(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf)
+
+	l.Log(LevelDebug, "Looking for file", slog.String("file", "orapass"), slog.Int("line", 3))
+
+	want := "DEBUG Looking for file file=orapass line=3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSlogLogger_RespectsHandlerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	l := NewSlogLogger(handler)
+
+	l.Log(LevelDebug, "should be filtered out")
+	if buf.Len() != 0 {
+		t.Errorf("expected LevelDebug to be filtered by an Info-level handler, got %q", buf.String())
+	}
+
+	l.Log(LevelInfo, "should be logged")
+	if buf.Len() == 0 {
+		t.Error("expected LevelInfo to pass an Info-level handler")
+	}
+}
+
+func TestParser_Logger_DefaultsToNop(t *testing.T) {
+	var p Parser
+	// logger() must never be nil, and logging through it must not panic
+	// even though nothing was configured.
+	p.logger().Log(LevelError, "no logger configured")
+}
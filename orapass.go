@@ -3,19 +3,50 @@
 // in scripts, applications, or config files.
 //
 // The orapass file is a colon separated file consisting of one line
-// per entry where each entry has five fields:
+// per entry where each entry has five required fields and an optional
+// sixth field:
 //
-//      host:port:database(SID):username:password
+//      host:port:database(SID):username:password[:service_name]
 //
-// Each of the first four fields can be a case-insensitive literal
-// value or "*" which acts as a match-anything-wildcard.
-// Blank and commented out lines are ignored.
+// Each of the host, port, database and username fields can be a
+// case-insensitive literal value or "*" which acts as a
+// match-anything-wildcard. The optional service_name field is matched
+// against Parser.ServiceName instead of (or in addition to) the SID;
+// a "*" there matches any non-empty Parser.ServiceName.
+//
+// A line may also be given as a TNS alias reference instead of a
+// literal host/port/database:
+//
+//      @tnsalias:username:password
+//
+// in which case the alias is resolved against tnsnames.ora (using the
+// TNS_ADMIN environment variable) to obtain the host, port and service
+// name. Blank and commented out lines are ignored.
+//
+// The orapass file to search is resolved in the following order of
+// precedence: the explicit Parser.OrapassFile field, the ORAPASSFILE
+// environment variable, then a per-user default (~/.orapass or
+// ~/orapass, with OS-appropriate equivalents on Windows). Use
+// ParseEnviron to resolve ORACLE_* and ORAPASSFILE from a
+// caller-supplied environment instead of the process environment, and
+// WithPasswordFile to override the file afterwards.
+//
+// Set Parser.Logger (built with NewSlogLogger or NewWriterLogger) to
+// receive structured Trace/Debug/Info/Warn/Error output describing
+// file lookups and match attempts; it is discarded by default.
+//
+// Long-running processes that call GetPasswd repeatedly (such as a
+// connection pool) should parse the orapass file once with NewCache
+// and set Parser.Resolver to a CacheResolver wrapping it, rather than
+// re-opening and re-scanning the file on every call.
 package orapass
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/user"
 	"path/filepath"
@@ -31,11 +62,71 @@ type Parser struct {
 	Host        string
 	Port        string
 	DbName      string
+	ServiceName string
 	Username    string
 	Password    string
 	OrapassFile string
-	files       []string
-	Debug       bool
+	// ConnectString, when set, is parsed as either an Oracle EZConnect
+	// string (user/pass@//host:port/service) or a TNS/RAC descriptor
+	// ((DESCRIPTION=...)) to populate Host, Port, DbName and
+	// ServiceName prior to lookup. Fields already set explicitly take
+	// precedence over values parsed from ConnectString.
+	ConnectString string
+	files         []string
+	// env, when set via ParseEnviron, is consulted instead of the
+	// process environment for ORACLE_* and ORAPASSFILE lookups.
+	env []string
+	// Resolver selects the secret-store backend used to retrieve the
+	// password, defaulting to FileResolver when nil.
+	Resolver Resolver
+	// Logger receives structured trace/debug/info/warn/error output
+	// describing file lookups and match attempts. Nil discards it.
+	Logger Logger
+}
+
+// ParseEnviron constructs a Parser whose ORACLE_* connection
+// parameters and ORAPASSFILE are read from env (a slice of "KEY=VALUE"
+// strings, as returned by os.Environ) rather than from the process
+// environment. This allows multiple independent connections within
+// one process to resolve different passfiles concurrently without
+// racing on os.Setenv.
+func ParseEnviron(env []string) Parser {
+	var p Parser
+	p.env = env
+
+	p.Host = p.envLookup("ORACLE_HOST")
+	p.Port = p.envLookup("ORACLE_PORT")
+	p.DbName = p.envLookup("ORACLE_SID")
+	p.Username = p.envLookup("ORACLE_USER")
+	p.OrapassFile = p.envLookup("ORAPASSFILE")
+
+	return p
+}
+
+// WithPasswordFile sets p.OrapassFile to f and returns p, for chaining.
+// ParseEnviron returns a Parser value, so assign it before calling:
+//
+//	p := orapass.ParseEnviron(os.Environ())
+//	p.WithPasswordFile("/etc/myapp/orapass")
+func (p *Parser) WithPasswordFile(f string) *Parser {
+	p.OrapassFile = f
+	return p
+}
+
+// envLookup returns the value of key from p.env if the Parser was
+// constructed with ParseEnviron, otherwise from the process
+// environment.
+func (p *Parser) envLookup(key string) string {
+	if p.env == nil {
+		return os.Getenv(key)
+	}
+	for _, kv := range p.env {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && k == key {
+			return v
+		}
+	}
+	return ""
 }
 
 // GetPasswd retrieves the password for the specified (host, port,
@@ -48,42 +139,42 @@ func (p *Parser) GetPasswd() (Parser, error) {
 		osUser = usr.Username
 	}
 
-	p.Host = coalesce([]string{p.Host, os.Getenv("ORACLE_HOST"), "localhost"})
-	p.Port = coalesce([]string{p.Port, os.Getenv("ORACLE_PORT"), "1521"})
-	p.DbName = coalesce([]string{p.DbName, os.Getenv("ORACLE_SID")})
-	p.Username = coalesce([]string{p.Username, os.Getenv("ORACLE_USER"), osUser})
-
-	var p2 Parser
-	err = p.findPasswordFile()
-	if err != nil {
-		return p2, err
-	}
-
-	switch {
-	case runtime.GOOS != "windows":
-		_, err = p.checkFilePerms()
+	if p.ConnectString != "" {
+		d, err := ParseConnectDescriptor(p.ConnectString)
 		if err != nil {
+			var p2 Parser
 			return p2, err
 		}
+		p.applyConnectDescriptor(d)
 	}
-	return p.searchFile()
+
+	p.Host = coalesce([]string{p.Host, p.envLookup("ORACLE_HOST"), "localhost"})
+	p.Port = coalesce([]string{p.Port, p.envLookup("ORACLE_PORT"), "1521"})
+	p.DbName = coalesce([]string{p.DbName, p.envLookup("ORACLE_SID")})
+	p.Username = coalesce([]string{p.Username, p.envLookup("ORACLE_USER"), osUser})
+
+	resolver := p.Resolver
+	if resolver == nil {
+		resolver = FileResolver{}
+	}
+	return resolver.Resolve(*p)
 }
 
 // findPasswordFile searches for an orapass file and returns the first one found
 func (p *Parser) findPasswordFile() error {
 
 	p.appendFileList(p.OrapassFile)
-	p.appendFileList(os.Getenv("ORAPASSFILE"))
+	p.appendFileList(p.envLookup("ORAPASSFILE"))
 
 	switch runtime.GOOS {
 	case "windows":
-		//os.Getenv("APPDATA") or maybe os.Getenv("LOCALAPPDATA")
-		dir := os.Getenv("APPDATA")
+		//p.envLookup("APPDATA") or maybe p.envLookup("LOCALAPPDATA")
+		dir := p.envLookup("APPDATA")
 		p.appendFileList(filepath.Join(dir, "oracle", ".orapass"))
 		p.appendFileList(filepath.Join(dir, "oracle", "orapass"))
 
 	default:
-		dir := os.Getenv("HOME")
+		dir := p.envLookup("HOME")
 		p.appendFileList(filepath.Join(dir, ".orapass"))
 		p.appendFileList(filepath.Join(dir, "orapass"))
 	}
@@ -99,13 +190,13 @@ func (p *Parser) findPasswordFile() error {
 		}
 	}
 
-	p.carp("No orapass file found")
+	p.logger().Log(LevelDebug, "No orapass file found")
 	return nil
 }
 
 func (p *Parser) appendFileList(f string) {
 	if f != "" {
-		p.carp(fmt.Sprintf("Adding %q to search list", f))
+		p.logger().Log(LevelDebug, "Adding file to search list", slog.String("file", f))
 		p.files = append(p.files, f)
 	}
 }
@@ -132,18 +223,32 @@ func (p *Parser) checkFilePerms() (bool, error) {
 func (p *Parser) searchFile() (Parser, error) {
 
 	var p2 Parser
-	p.carp(fmt.Sprintf("Searching %q for %s/%s", p.OrapassFile, p.Username, p.DbName))
+	p.logger().Log(LevelInfo, "Searching orapass file",
+		slog.String("file", p.OrapassFile),
+		slog.String("field", "username/dbname"),
+		slog.String("username", p.Username),
+		slog.String("dbname", p.DbName))
 
 	re := regexp.MustCompile("^ *#")
 
-	file, err := os.Open(p.OrapassFile)
+	data, err := os.ReadFile(p.OrapassFile)
 	if err != nil {
 		return p2, err
 	}
-	defer file.Close()
+
+	if isEncrypted(data) {
+		passphrase, err := resolvePassphrase()
+		if err != nil {
+			return p2, err
+		}
+		data, err = DecryptFile(data, passphrase)
+		if err != nil {
+			return p2, err
+		}
+	}
 
 	i := 0
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		i++
 
@@ -154,36 +259,59 @@ func (p *Parser) searchFile() (Parser, error) {
 			continue
 		}
 
-		p.carp(fmt.Sprintf("    Parsing line %d", i))
-		tokens := strings.SplitN(line, ":", 5)
+		p.logger().Log(LevelTrace, "Parsing line",
+			slog.String("file", p.OrapassFile), slog.Int("line", i))
+
+		if strings.HasPrefix(line, "@") {
+			p2, matched, err := p.chkAliasLine(line)
+			switch {
+			case err != nil:
+				return p2, err
+			case matched:
+				p.logger().Log(LevelDebug, "Match detected",
+					slog.String("file", p.OrapassFile), slog.Int("line", i))
+				return p2, nil
+			}
+			continue
+		}
+
+		tokens := strings.SplitN(line, ":", 6)
 		if len(tokens) < 5 {
 			continue
 		}
 
-		hostMatch := p.chkForMatch(p.Host, tokens[0])
-		portMatch := p.chkForMatch(p.Port, tokens[1])
-		dbNameMatch := p.chkForMatch(p.DbName, tokens[2])
-		userMatch := p.chkForMatch(p.Username, tokens[3])
+		var serviceName string
+		if len(tokens) == 6 {
+			serviceName = tokens[5]
+		}
+
+		hostMatch := chkForMatch(p.Host, tokens[0])
+		portMatch := chkForMatch(p.Port, tokens[1])
+		dbNameMatch := chkForMatch(p.DbName, tokens[2]) ||
+			(p.ServiceName != "" && chkForMatch(p.ServiceName, serviceName))
+		userMatch := chkForMatch(p.Username, tokens[3])
 
 		if !hostMatch {
-			p.carp("        Host does not match")
+			p.logger().Log(LevelTrace, "Field does not match", slog.String("field", "host"), slog.Int("line", i))
 		}
 		if !portMatch {
-			p.carp("        Port does not match")
+			p.logger().Log(LevelTrace, "Field does not match", slog.String("field", "port"), slog.Int("line", i))
 		}
 		if !dbNameMatch {
-			p.carp("        DB name does not match")
+			p.logger().Log(LevelTrace, "Field does not match", slog.String("field", "dbname"), slog.Int("line", i))
 		}
 		if !userMatch {
-			p.carp("        Username does not match")
+			p.logger().Log(LevelTrace, "Field does not match", slog.String("field", "username"), slog.Int("line", i))
 		}
 
 		if hostMatch && portMatch && dbNameMatch && userMatch {
-			p.carp("        Match detected")
+			p.logger().Log(LevelDebug, "Match detected",
+				slog.String("file", p.OrapassFile), slog.Int("line", i))
 
-			p2.Host = p.pickParm(p.Host, tokens[0])
-			p2.Port = p.pickParm(p.Port, tokens[1])
-			p2.DbName = p.pickParm(p.DbName, tokens[2])
+			p2.Host = pickParm(p.Host, tokens[0])
+			p2.Port = pickParm(p.Port, tokens[1])
+			p2.DbName = pickParm(p.DbName, tokens[2])
+			p2.ServiceName = serviceName
 			p2.Username = tokens[3]
 			p2.Password = tokens[4]
 
@@ -201,7 +329,7 @@ func (p *Parser) searchFile() (Parser, error) {
 // fileExists checks to ensure that the specified file exists and is a regular file
 func (p *Parser) fileExists(pathname string) (bool, error) {
 
-	p.carp(fmt.Sprintf("Looking for file %q", pathname))
+	p.logger().Log(LevelDebug, "Looking for file", slog.String("file", pathname))
 	fi, err := os.Stat(pathname)
 	if err != nil {
 		// For our purposes, a non-existent file is not considered an error
@@ -211,20 +339,76 @@ func (p *Parser) fileExists(pathname string) (bool, error) {
 		return false, err
 	}
 
-	p.carp(fmt.Sprintf("Found %q", pathname))
+	p.logger().Log(LevelDebug, "Found file", slog.String("file", pathname))
 	switch mode := fi.Mode(); {
 	case mode.IsRegular():
 		return true, nil
 	}
 
-	p.carp(fmt.Sprintf("%q is not a regular file", pathname))
+	p.logger().Log(LevelDebug, "Not a regular file", slog.String("file", pathname))
 	return false, nil
 }
 
+// applyConnectDescriptor copies the Host, Port, DbName and ServiceName
+// from d into p, but only for fields that were not already set
+// explicitly.
+func (p *Parser) applyConnectDescriptor(d ConnectDescriptor) {
+	p.Host = coalesce([]string{p.Host, d.Host})
+	p.Port = coalesce([]string{p.Port, d.Port})
+	p.DbName = coalesce([]string{p.DbName, d.DbName})
+	p.ServiceName = coalesce([]string{p.ServiceName, d.ServiceName})
+}
+
+// chkAliasLine handles an "@alias:username:password" line by resolving
+// the alias via tnsnames.ora and matching it against the requested
+// host/port/database/service (when specified) and username.
+func (p *Parser) chkAliasLine(line string) (Parser, bool, error) {
+	var p2 Parser
+
+	tokens := strings.SplitN(strings.TrimPrefix(line, "@"), ":", 3)
+	if len(tokens) < 3 {
+		return p2, false, nil
+	}
+	alias, username, password := tokens[0], tokens[1], tokens[2]
+
+	if !chkForMatch(p.Username, username) {
+		p.logger().Log(LevelTrace, "Field does not match", slog.String("field", "username"))
+		return p2, false, nil
+	}
+
+	d, err := resolveTNSAlias(p.envLookup, alias)
+	if err != nil {
+		p.logger().Log(LevelWarn, "Could not resolve TNS alias",
+			slog.String("field", "alias"), slog.String("alias", alias), slog.Any("error", err))
+		return p2, false, nil
+	}
+
+	hostMatch := chkForMatch(p.Host, d.Host)
+	portMatch := chkForMatch(p.Port, d.Port)
+	// d.DbName is empty for the overwhelming majority of real
+	// tnsnames.ora entries, which give only a SERVICE_NAME and no SID.
+	// chkForMatch("", "") would otherwise report a match by equality,
+	// so an empty d.DbName is only matched through ServiceName.
+	dbMatch := (d.DbName != "" && chkForMatch(p.DbName, d.DbName)) ||
+		(p.ServiceName != "" && chkForMatch(p.ServiceName, d.ServiceName))
+
+	if !hostMatch || !portMatch || !dbMatch {
+		return p2, false, nil
+	}
+
+	p2.Host = d.Host
+	p2.Port = d.Port
+	p2.DbName = d.DbName
+	p2.ServiceName = d.ServiceName
+	p2.Username = username
+	p2.Password = password
+	return p2, true, nil
+}
+
 // chkForMatch checks the calling parameter against the same file
 // parameter, taking into account wild-card characters and returns true
 // on a match
-func (p *Parser) chkForMatch(callingParm, fileParm string) bool {
+func chkForMatch(callingParm, fileParm string) bool {
 	switch {
 	case strings.ToUpper(callingParm) == strings.ToUpper(fileParm):
 		return true
@@ -236,21 +420,13 @@ func (p *Parser) chkForMatch(callingParm, fileParm string) bool {
 
 // pickParm chooses between the calling parameter and file parameter
 // and returns the appropriate value
-func (p *Parser) pickParm(callingParm, fileParm string) string {
+func pickParm(callingParm, fileParm string) string {
 	if fileParm != "*" && fileParm != "" {
 		return fileParm
 	}
 	return callingParm
 }
 
-func (p *Parser) carp(s string) {
-	if s != "" {
-		if p.Debug {
-			os.Stderr.WriteString(s)
-		}
-	}
-}
-
 // coalesce picks the first non-empty string from a list
 func coalesce(s []string) string {
 	for _, v := range s {
@@ -2,6 +2,7 @@ package orapass
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 	"testing"
 )
@@ -39,7 +40,9 @@ func Test001(t *testing.T) {
 	p.DbName = "emp"
 	p.Username = "scott"
 	p.OrapassFile = ""
-	p.Debug = debug()
+	if debug() {
+		p.Logger = NewWriterLogger(os.Stderr)
+	}
 
 	_, _ = p.GetPasswd()
 }
@@ -54,7 +57,9 @@ func Test002(t *testing.T) {
 	p.DbName = "emp"
 	p.Username = "scott"
 	p.OrapassFile = ".git"
-	p.Debug = debug()
+	if debug() {
+		p.Logger = NewWriterLogger(os.Stderr)
+	}
 
 	_, _ = p.GetPasswd()
 }
@@ -73,7 +78,9 @@ func Test003(t *testing.T) {
 		p.DbName = "emp"
 		p.Username = "scott"
 		p.OrapassFile = "run_test.sh"
-		p.Debug = debug()
+		if debug() {
+			p.Logger = NewWriterLogger(os.Stderr)
+		}
 
 		_, err := p.GetPasswd()
 		if err == nil {
@@ -128,7 +135,9 @@ func Test004(t *testing.T) {
 		sent.DbName = c.DbName
 		sent.Username = c.Username
 		sent.OrapassFile = "orapass" // we want the local testing item, not one that the user is actually using...
-		sent.Debug = debug()
+		if debug() {
+			sent.Logger = NewWriterLogger(os.Stderr)
+		}
 
 		got, err := sent.GetPasswd()
 
@@ -0,0 +1,34 @@
+package orapass
+
+import "runtime"
+
+// Resolver resolves the password for the (host, port, database,
+// service, username) carried by p, returning a Parser with Password
+// (and any fields filled in from a match, such as a wildcard Host or
+// DbName) populated.
+type Resolver interface {
+	Resolve(p Parser) (Parser, error)
+}
+
+// FileResolver resolves passwords from an orapass file, located via
+// Parser.OrapassFile, ORAPASSFILE, or the per-user default as
+// documented in the package doc comment. This is the Resolver used by
+// GetPasswd when Parser.Resolver is nil.
+type FileResolver struct{}
+
+// Resolve implements Resolver.
+func (FileResolver) Resolve(p Parser) (Parser, error) {
+	var p2 Parser
+
+	err := p.findPasswordFile()
+	if err != nil {
+		return p2, err
+	}
+
+	if runtime.GOOS != "windows" {
+		if _, err := p.checkFilePerms(); err != nil {
+			return p2, err
+		}
+	}
+	return p.searchFile()
+}
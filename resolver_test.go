@@ -0,0 +1,71 @@
+package orapass
+
+import (
+	"errors"
+	"testing"
+)
+
+type stubResolver struct {
+	password string
+	err      error
+}
+
+func (s stubResolver) Resolve(p Parser) (Parser, error) {
+	if s.err != nil {
+		return Parser{}, s.err
+	}
+	p2 := p
+	p2.Password = s.password
+	return p2, nil
+}
+
+func TestChainResolver_TriesInOrder(t *testing.T) {
+	chain := ChainResolver{
+		stubResolver{err: errors.New("first resolver failed")},
+		stubResolver{err: errors.New("second resolver failed")},
+		stubResolver{password: "tiger"},
+	}
+
+	got, err := chain.Resolve(Parser{Username: "scott"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.Password != "tiger" {
+		t.Errorf("got password %q, want %q", got.Password, "tiger")
+	}
+}
+
+func TestChainResolver_StopsAtFirstSuccess(t *testing.T) {
+	chain := ChainResolver{
+		stubResolver{password: "first"},
+		stubResolver{password: "second"},
+	}
+
+	got, err := chain.Resolve(Parser{Username: "scott"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.Password != "first" {
+		t.Errorf("got password %q, want %q from the first resolver", got.Password, "first")
+	}
+}
+
+func TestChainResolver_AllFail(t *testing.T) {
+	wantErr := errors.New("last resolver failed")
+	chain := ChainResolver{
+		stubResolver{err: errors.New("first resolver failed")},
+		stubResolver{err: wantErr},
+	}
+
+	_, err := chain.Resolve(Parser{Username: "scott"})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("got error %v, want the last resolver's error %v", err, wantErr)
+	}
+}
+
+func TestChainResolver_Empty(t *testing.T) {
+	var chain ChainResolver
+	if _, err := chain.Resolve(Parser{Username: "scott"}); err == nil {
+		t.Error("an empty ChainResolver should return an error")
+	}
+}
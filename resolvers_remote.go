@@ -0,0 +1,208 @@
+package orapass
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/secrets"
+	"github.com/zalando/go-keyring"
+)
+
+// VaultResolver resolves passwords from a HashiCorp Vault KV v2
+// secrets engine. Addr and Token default to the VAULT_ADDR and
+// VAULT_TOKEN environment variables. PathTemplate is rendered against
+// the requested connection parameters before lookup, e.g.
+// "secret/oracle/{host}/{db}/{user}".
+type VaultResolver struct {
+	Addr         string
+	Token        string
+	PathTemplate string
+	Client       *http.Client
+}
+
+// Resolve implements Resolver.
+func (r VaultResolver) Resolve(p Parser) (Parser, error) {
+	var p2 Parser
+
+	addr := coalesce([]string{r.Addr, p.envLookup("VAULT_ADDR")})
+	token := coalesce([]string{r.Token, p.envLookup("VAULT_TOKEN")})
+	if addr == "" || token == "" {
+		return p2, errors.New("VAULT_ADDR and VAULT_TOKEN must be set to use VaultResolver")
+	}
+
+	path := r.renderPath(p)
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimRight(addr, "/"), path)
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return p2, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return p2, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return p2, fmt.Errorf("vault returned status %s for %s", resp.Status, url)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return p2, err
+	}
+
+	password, ok := body.Data.Data["password"]
+	if !ok {
+		return p2, fmt.Errorf("no password field at %s", path)
+	}
+
+	p2 = p
+	p2.Password = password
+	return p2, nil
+}
+
+// renderPath substitutes {host}, {port}, {db}, {service} and {user}
+// placeholders in r.PathTemplate (or a default template, if unset)
+// with the corresponding fields of p.
+func (r VaultResolver) renderPath(p Parser) string {
+	tmpl := r.PathTemplate
+	if tmpl == "" {
+		tmpl = "secret/oracle/{host}/{db}/{user}"
+	}
+	return strings.NewReplacer(
+		"{host}", p.Host,
+		"{port}", p.Port,
+		"{db}", p.DbName,
+		"{service}", p.ServiceName,
+		"{user}", p.Username,
+	).Replace(tmpl)
+}
+
+// OCIVaultResolver resolves passwords from an OCI Vault secret,
+// addressed by OCID, using the OCI Go SDK's default configuration
+// provider (the same credentials resolution the OCI CLI uses).
+// SecretID may contain the same {host}/{port}/{db}/{service}/{user}
+// placeholders as VaultResolver.PathTemplate.
+type OCIVaultResolver struct {
+	SecretID string
+}
+
+// Resolve implements Resolver.
+func (r OCIVaultResolver) Resolve(p Parser) (Parser, error) {
+	var p2 Parser
+
+	if r.SecretID == "" {
+		return p2, errors.New("OCIVaultResolver.SecretID must be set")
+	}
+	secretID := strings.NewReplacer(
+		"{host}", p.Host,
+		"{port}", p.Port,
+		"{db}", p.DbName,
+		"{service}", p.ServiceName,
+		"{user}", p.Username,
+	).Replace(r.SecretID)
+
+	provider := common.DefaultConfigProvider()
+	client, err := secrets.NewSecretsClientWithConfigurationProvider(provider)
+	if err != nil {
+		return p2, err
+	}
+
+	resp, err := client.GetSecretBundle(context.Background(), secrets.GetSecretBundleRequest{
+		SecretId: &secretID,
+	})
+	if err != nil {
+		return p2, err
+	}
+
+	content, ok := resp.SecretBundleContent.(secrets.Base64SecretBundleContentDetails)
+	if !ok {
+		return p2, errors.New("unexpected OCI secret bundle content type")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*content.Content)
+	if err != nil {
+		return p2, err
+	}
+
+	p2 = p
+	p2.Password = string(decoded)
+	return p2, nil
+}
+
+// KeyringResolver resolves passwords from the OS-native credential
+// store (macOS Keychain, Windows Credential Manager, or a Secret
+// Service implementation on Linux) via go-keyring. Service defaults
+// to "orapass" and KeyFunc defaults to a "host:port:db:user" key.
+type KeyringResolver struct {
+	Service string
+	KeyFunc func(p Parser) string
+}
+
+// Resolve implements Resolver.
+func (r KeyringResolver) Resolve(p Parser) (Parser, error) {
+	var p2 Parser
+
+	service := r.Service
+	if service == "" {
+		service = "orapass"
+	}
+
+	keyFunc := r.KeyFunc
+	if keyFunc == nil {
+		keyFunc = defaultKeyringKey
+	}
+
+	password, err := keyring.Get(service, keyFunc(p))
+	if err != nil {
+		return p2, err
+	}
+
+	p2 = p
+	p2.Password = password
+	return p2, nil
+}
+
+func defaultKeyringKey(p Parser) string {
+	return fmt.Sprintf("%s:%s:%s:%s", p.Host, p.Port, p.DbName, p.Username)
+}
+
+// ChainResolver tries each Resolver in order and returns the result of
+// the first one that does not return an error.
+type ChainResolver []Resolver
+
+// Resolve implements Resolver.
+func (c ChainResolver) Resolve(p Parser) (Parser, error) {
+	var p2 Parser
+	var err error
+
+	if len(c) == 0 {
+		return p2, errors.New("ChainResolver has no resolvers configured")
+	}
+
+	for _, r := range c {
+		p2, err = r.Resolve(p)
+		if err == nil {
+			return p2, nil
+		}
+	}
+	return p2, err
+}
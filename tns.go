@@ -0,0 +1,144 @@
+package orapass
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ConnectDescriptor holds the components of an Oracle connect string
+// once it has been parsed out of an EZConnect URL or a TNS/RAC
+// descriptor.
+type ConnectDescriptor struct {
+	Host        string
+	Port        string
+	DbName      string
+	ServiceName string
+}
+
+var ezConnectRe = regexp.MustCompile(`^//([^:/]+)(?::(\d+))?/(.+)$`)
+
+// ParseConnectDescriptor parses an Oracle EZConnect string
+// (user/pass@//host:port/service) or a TNS/RAC descriptor
+// ((DESCRIPTION=(ADDRESS=...)(CONNECT_DATA=(SERVICE_NAME=...)))) into
+// its component Host, Port, DbName and ServiceName values.
+func ParseConnectDescriptor(s string) (ConnectDescriptor, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case strings.Contains(strings.ToUpper(s), "(DESCRIPTION"):
+		return parseTNSDescriptor(s)
+	case strings.Contains(s, "//"):
+		return parseEZConnect(s)
+	}
+	return ConnectDescriptor{}, errors.New("unrecognized connect string format")
+}
+
+// parseEZConnect parses the //host:port/service portion of an
+// EZConnect string, discarding any leading user/password@ prefix. The
+// prefix is located by the "@//" that introduces the //host portion
+// rather than by a charset that excludes "/", since a password
+// containing the ordinary user/password@ separator (e.g.
+// scott/tiger@//dbhost:1521/orcl) would otherwise fail to match.
+func parseEZConnect(s string) (ConnectDescriptor, error) {
+	rest := s
+	if i := strings.Index(s, "@//"); i != -1 {
+		rest = s[i+1:]
+	}
+
+	m := ezConnectRe.FindStringSubmatch(rest)
+	if m == nil {
+		return ConnectDescriptor{}, fmt.Errorf("%q is not a valid EZConnect string", s)
+	}
+	return ConnectDescriptor{
+		Host:        m[1],
+		Port:        m[2],
+		ServiceName: m[3],
+	}, nil
+}
+
+// parseTNSDescriptor extracts HOST, PORT, SERVICE_NAME and SID values
+// out of a TNS descriptor such as
+// (DESCRIPTION=(ADDRESS=(HOST=dbhost)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl))).
+func parseTNSDescriptor(s string) (ConnectDescriptor, error) {
+	d := ConnectDescriptor{
+		Host:        firstSubmatch(s, `(?i)\(HOST\s*=\s*([^)]+)\)`),
+		Port:        firstSubmatch(s, `(?i)\(PORT\s*=\s*([^)]+)\)`),
+		ServiceName: firstSubmatch(s, `(?i)\(SERVICE_NAME\s*=\s*([^)]+)\)`),
+		DbName:      firstSubmatch(s, `(?i)\(SID\s*=\s*([^)]+)\)`),
+	}
+	if d.Host == "" {
+		return d, fmt.Errorf("could not parse TNS descriptor: no HOST found in %q", s)
+	}
+	return d, nil
+}
+
+// firstSubmatch returns the first capture group of pattern matched
+// against s, or "" if pattern does not match.
+func firstSubmatch(s, pattern string) string {
+	m := regexp.MustCompile(pattern).FindStringSubmatch(s)
+	if len(m) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(m[1])
+}
+
+// resolveTNSAlias looks up alias in the tnsnames.ora file in the
+// directory named by the TNS_ADMIN environment variable and returns the parsed connect
+// descriptor for that alias. getenv supplies the environment lookup,
+// so callers holding a Parser built via ParseEnviron can pass
+// p.envLookup to resolve TNS_ADMIN from the Parser's own env instead
+// of the process environment.
+func resolveTNSAlias(getenv func(string) string, alias string) (ConnectDescriptor, error) {
+	var d ConnectDescriptor
+
+	dir := getenv("TNS_ADMIN")
+	if dir == "" {
+		return d, errors.New("TNS_ADMIN is not set")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tnsnames.ora"))
+	if err != nil {
+		return d, err
+	}
+
+	descriptor, ok := findTNSAliasEntry(string(data), alias)
+	if !ok {
+		return d, fmt.Errorf("alias %q not found in tnsnames.ora", alias)
+	}
+	return parseTNSDescriptor(descriptor)
+}
+
+// findTNSAliasEntry scans the contents of a tnsnames.ora file for the
+// named alias and returns the balanced-parenthesis descriptor that
+// follows its "=".
+func findTNSAliasEntry(contents, alias string) (string, bool) {
+	aliasRe := regexp.MustCompile(`(?im)^\s*` + regexp.QuoteMeta(alias) + `\s*=`)
+	loc := aliasRe.FindStringIndex(contents)
+	if loc == nil {
+		return "", false
+	}
+
+	rest := contents[loc[1]:]
+	start := strings.Index(rest, "(")
+	if start == -1 {
+		return "", false
+	}
+
+	depth := 0
+	for i, r := range rest[start:] {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return rest[start : start+i+1], true
+			}
+		}
+	}
+	return "", false
+}
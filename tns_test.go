@@ -0,0 +1,86 @@
+package orapass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConnectDescriptor_EZConnect(t *testing.T) {
+	cases := []struct {
+		name        string
+		in          string
+		wantHost    string
+		wantPort    string
+		wantService string
+	}{
+		{"user and password", "scott/tiger@//dbhost:1521/orcl", "dbhost", "1521", "orcl"},
+		{"user only", "scott@//dbhost:1521/orcl", "dbhost", "1521", "orcl"},
+		{"no credentials", "//dbhost:1521/orcl", "dbhost", "1521", "orcl"},
+		{"no port", "scott/tiger@//dbhost/orcl", "dbhost", "", "orcl"},
+		{"password containing @", "scott/t@ger@//dbhost:1521/orcl", "dbhost", "1521", "orcl"},
+	}
+
+	for _, c := range cases {
+		got, err := ParseConnectDescriptor(c.in)
+		if err != nil {
+			t.Errorf("%s: unexpected error parsing %q: %s", c.name, c.in, err)
+			continue
+		}
+		if got.Host != c.wantHost || got.Port != c.wantPort || got.ServiceName != c.wantService {
+			t.Errorf("%s: parsing %q: got host=%q port=%q service=%q, want host=%q port=%q service=%q",
+				c.name, c.in, got.Host, got.Port, got.ServiceName, c.wantHost, c.wantPort, c.wantService)
+		}
+	}
+}
+
+func TestParseConnectDescriptor_TNSDescriptor(t *testing.T) {
+	in := "(DESCRIPTION=(ADDRESS=(HOST=dbhost)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=orcl)))"
+	got, err := ParseConnectDescriptor(in)
+	if err != nil {
+		t.Fatalf("unexpected error parsing %q: %s", in, err)
+	}
+	if got.Host != "dbhost" || got.Port != "1521" || got.ServiceName != "orcl" {
+		t.Errorf("parsing %q: got %+v", in, got)
+	}
+}
+
+// TestResolveTNSAlias_UsesProvidedGetenv verifies that resolveTNSAlias
+// consults the getenv it was given rather than the process
+// environment, so a Parser built via ParseEnviron with its own env
+// slice resolves TNS_ADMIN independently of os.Setenv.
+func TestResolveTNSAlias_UsesProvidedGetenv(t *testing.T) {
+	realDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(realDir, "tnsnames.ora"), []byte(
+		"REALALIAS = (DESCRIPTION=(ADDRESS=(HOST=realhost)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=real)))"),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TNS_ADMIN", realDir)
+
+	ownDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(ownDir, "tnsnames.ora"), []byte(
+		"OWNALIAS = (DESCRIPTION=(ADDRESS=(HOST=ownhost)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=own)))"),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+
+	getenv := func(key string) string {
+		if key == "TNS_ADMIN" {
+			return ownDir
+		}
+		return ""
+	}
+
+	d, err := resolveTNSAlias(getenv, "OWNALIAS")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d.Host != "ownhost" {
+		t.Errorf("got host %q, want %q (should have used the provided getenv, not the process TNS_ADMIN)", d.Host, "ownhost")
+	}
+
+	if _, err := resolveTNSAlias(getenv, "REALALIAS"); err == nil {
+		t.Error("REALALIAS only exists in the process TNS_ADMIN dir; the provided getenv should not have found it")
+	}
+}